@@ -0,0 +1,133 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// credentialsFile is the name of the file, relative to the user's
+// home directory, consulted by DefaultCredentialProvider.
+const credentialsFile = ".pprof/credentials"
+
+// DefaultCredentialProvider is a CredentialProvider that looks up src
+// against entries read from ~/.pprof/credentials and from environment
+// variables, in that order, returning the first match. It is not
+// installed automatically: a nil Options.Creds attaches no credentials.
+//
+// Each line of the credentials file has the form:
+//
+//	<src-prefix> <header-name>: <header-value>
+//
+// Lines beginning with # and blank lines are ignored. src-prefix is
+// matched against src with strings.HasPrefix, so a single entry can
+// cover every endpoint under a host.
+//
+// If no entry matches src, DefaultCredentialProvider falls back to
+// the PPROF_AUTH_TOKEN environment variable, sending it as a bearer
+// token, and to PPROF_TLS_CERT/PPROF_TLS_KEY for a client certificate.
+type DefaultCredentialProvider struct{}
+
+// Credentials implements the CredentialProvider interface.
+func (DefaultCredentialProvider) Credentials(src string) (http.Header, *tls.Config, error) {
+	if header, err := credentialsFromFile(src); err != nil {
+		return nil, nil, err
+	} else if header != nil {
+		return header, nil, nil
+	}
+
+	tlsConfig, err := tlsConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentialsFromEnv(src), tlsConfig, nil
+}
+
+func credentialsFromFile(src string) (http.Header, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil
+	}
+
+	f, err := os.Open(filepath.Join(home, credentialsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var header http.Header
+	var malformed error
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 || !strings.HasPrefix(src, fields[0]) {
+			continue
+		}
+		name, value, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			// Keep scanning: a later malformed line must not discard
+			// a header already matched from an earlier one. Report
+			// it only if no entry ends up matching at all.
+			if malformed == nil {
+				malformed = fmt.Errorf("malformed credentials entry for %s", fields[0])
+			}
+			continue
+		}
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if header != nil {
+		return header, nil
+	}
+	return nil, malformed
+}
+
+func credentialsFromEnv(src string) http.Header {
+	token := os.Getenv("PPROF_AUTH_TOKEN")
+	if token == "" {
+		return nil
+	}
+	return http.Header{"Authorization": []string{"Bearer " + token}}
+}
+
+func tlsConfigFromEnv() (*tls.Config, error) {
+	cert, key := os.Getenv("PPROF_TLS_CERT"), os.Getenv("PPROF_TLS_KEY")
+	if cert == "" || key == "" {
+		return nil, nil
+	}
+	pair, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("loading PPROF_TLS_CERT/PPROF_TLS_KEY: %v", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{pair}}, nil
+}
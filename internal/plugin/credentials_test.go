@@ -0,0 +1,109 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCredentialsFile(t *testing.T, home, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(home, ".pprof"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, credentialsFile), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCredentialsFromFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCredentialsFile(t, home, "# comment\nhttps://example.com Authorization: Bearer abc123\n")
+
+	header, err := credentialsFromFile("https://example.com/profile")
+	if err != nil {
+		t.Fatalf("credentialsFromFile: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "Bearer abc123" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer abc123")
+	}
+}
+
+func TestCredentialsFromFileMalformedLineKeepsPriorMatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCredentialsFile(t, home,
+		"https://example.com Authorization: Bearer abc123\n"+
+			"https://example.com this-line-has-no-colon\n")
+
+	header, err := credentialsFromFile("https://example.com/profile")
+	if err != nil {
+		t.Fatalf("credentialsFromFile: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "Bearer abc123" {
+		t.Errorf("Authorization = %q, want %q (malformed line after a match must not discard it)", got, "Bearer abc123")
+	}
+}
+
+func TestCredentialsFromFileMalformedLineReportedWhenNoMatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCredentialsFile(t, home, "https://example.com this-line-has-no-colon\n")
+
+	header, err := credentialsFromFile("https://example.com/profile")
+	if header != nil {
+		t.Errorf("header = %v, want nil", header)
+	}
+	if err == nil {
+		t.Error("err = nil, want malformed entry error")
+	}
+}
+
+func TestCredentialsFromFileNoMatch(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCredentialsFile(t, home, "https://other.example.com Authorization: Bearer abc123\n")
+
+	header, err := credentialsFromFile("https://example.com/profile")
+	if err != nil {
+		t.Fatalf("credentialsFromFile: %v", err)
+	}
+	if header != nil {
+		t.Errorf("header = %v, want nil", header)
+	}
+}
+
+func TestCredentialsFromEnv(t *testing.T) {
+	t.Setenv("PPROF_AUTH_TOKEN", "xyz")
+	header := credentialsFromEnv("https://example.com/profile")
+	if got := header.Get("Authorization"); got != "Bearer xyz" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer xyz")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DebuginfodResolver is a DebugInfoResolver that speaks the debuginfod
+// HTTP protocol, querying the servers named by DEBUGINFOD_URLS (a
+// space-separated list of base URLs, as used by elfutils and gdb) and
+// caching successful responses under $XDG_CACHE_HOME/pprof/debuginfod.
+// It is not installed automatically when Options.DebugInfo is nil.
+type DebuginfodResolver struct {
+	// Servers overrides DEBUGINFOD_URLS if non-empty.
+	Servers []string
+
+	// CacheDir overrides the default cache directory if non-empty.
+	CacheDir string
+
+	// Client is used to issue requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Resolve implements the DebugInfoResolver interface.
+func (d DebuginfodResolver) Resolve(buildID string) (io.ReadCloser, error) {
+	cacheDir, err := d.cacheDir()
+	if err == nil {
+		cached := filepath.Join(cacheDir, buildID, "debuginfo")
+		if f, err := os.Open(cached); err == nil {
+			return f, nil
+		}
+	}
+
+	servers := d.servers()
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no debuginfod servers configured (set DEBUGINFOD_URLS)")
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var errs []string
+	for _, server := range servers {
+		url := strings.TrimSuffix(server, "/") + "/buildid/" + buildID + "/debuginfo"
+		resp, err := client.Get(url)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", server, err))
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			errs = append(errs, fmt.Sprintf("%s: %s", server, resp.Status))
+			continue
+		}
+
+		if cacheDir != "" {
+			storeErr := d.store(cacheDir, buildID, resp.Body)
+			resp.Body.Close()
+			if storeErr == nil {
+				return os.Open(filepath.Join(cacheDir, buildID, "debuginfo"))
+			}
+			// Fall through and serve the already-consumed body's
+			// replacement by re-requesting below is not possible, so
+			// just report the caching failure and try the next server.
+			errs = append(errs, fmt.Sprintf("%s: caching response: %v", server, storeErr))
+			continue
+		}
+		return resp.Body, nil
+	}
+
+	return nil, fmt.Errorf("could not resolve debug info for build ID %s: %s", buildID, strings.Join(errs, "; "))
+}
+
+func (d DebuginfodResolver) servers() []string {
+	if len(d.Servers) > 0 {
+		return d.Servers
+	}
+	if urls := os.Getenv("DEBUGINFOD_URLS"); urls != "" {
+		return strings.Fields(urls)
+	}
+	return nil
+}
+
+func (d DebuginfodResolver) cacheDir() (string, error) {
+	if d.CacheDir != "" {
+		return d.CacheDir, nil
+	}
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, "pprof", "debuginfod"), nil
+}
+
+func (d DebuginfodResolver) store(cacheDir, buildID string, r io.Reader) error {
+	dir := filepath.Join(cacheDir, buildID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "debuginfo-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, "debuginfo"))
+}
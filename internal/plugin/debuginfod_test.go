@@ -0,0 +1,112 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDebuginfodResolverCachesResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "debug-data")
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	d := DebuginfodResolver{Servers: []string{srv.URL}, CacheDir: cacheDir}
+
+	r, err := d.Resolve("abc123")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "debug-data" {
+		t.Errorf("data = %q, want %q", data, "debug-data")
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, "abc123", "debuginfo")); err != nil {
+		t.Errorf("cached file not found: %v", err)
+	}
+}
+
+func TestDebuginfodResolverCacheHit(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, "debug-data")
+	}))
+	defer srv.Close()
+
+	cacheDir := t.TempDir()
+	d := DebuginfodResolver{Servers: []string{srv.URL}, CacheDir: cacheDir}
+
+	for i := 0; i < 2; i++ {
+		r, err := d.Resolve("abc123")
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		r.Close()
+	}
+	if requests != 1 {
+		t.Errorf("server got %d requests, want 1 (second Resolve should hit the cache)", requests)
+	}
+}
+
+func TestDebuginfodResolverReportsCachingFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "debug-data")
+	}))
+	defer srv.Close()
+
+	// Point CacheDir at a path that can't be created (a file, not a
+	// directory, in the way) so store() fails.
+	base := t.TempDir()
+	blocker := filepath.Join(base, "blocker")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := filepath.Join(blocker, "debuginfod")
+	d := DebuginfodResolver{Servers: []string{srv.URL}, CacheDir: cacheDir}
+
+	_, err := d.Resolve("abc123")
+	if err == nil {
+		t.Fatal("Resolve succeeded, want error from failed caching")
+	}
+	got := err.Error()
+	if strings.Contains(got, "<nil>") {
+		t.Errorf("error %q swallows the real caching failure behind a nil value", got)
+	}
+	if !strings.Contains(got, "caching response") {
+		t.Errorf("error %q does not mention the caching failure", got)
+	}
+}
+
+func TestDebuginfodResolverNoServers(t *testing.T) {
+	d := DebuginfodResolver{}
+	t.Setenv("DEBUGINFOD_URLS", "")
+	if _, err := d.Resolve("abc123"); err == nil {
+		t.Error("Resolve succeeded with no servers configured, want error")
+	}
+}
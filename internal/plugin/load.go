@@ -0,0 +1,49 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LoadPath scans PPROF_PLUGIN_PATH, a colon-separated list of
+// directories, for Go plugins (files built with -buildmode=plugin,
+// named *.so) and loads each one so that its init functions can call
+// Register. LoadPath is a no-op if PPROF_PLUGIN_PATH is unset.
+//
+// Dynamic loading requires the platform-specific "plugin" package
+// from the standard library, which is only available on Linux,
+// FreeBSD and macOS; see loadPlugin in load_plugin.go and
+// load_plugin_unsupported.go. Plugins for other platforms must be
+// registered at compile time instead.
+func LoadPath() error {
+	path := os.Getenv("PPROF_PLUGIN_PATH")
+	if path == "" {
+		return nil
+	}
+	for _, dir := range filepath.SplitList(path) {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.so"))
+		if err != nil {
+			return err
+		}
+		for _, so := range matches {
+			if err := loadPlugin(so); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || freebsd || darwin
+
+package plugin
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// loadPlugin opens the Go plugin at path. Its init functions run as
+// a side effect of the Open call, which is expected to call Register.
+//
+// Unlike a compile-time Register call, whose name collisions are
+// deterministic and caught during development, the name registered
+// by a plugin discovered under PPROF_PLUGIN_PATH is controlled by
+// whoever built that .so and can collide with another, unrelated
+// plugin at runtime. Register's panic-on-duplicate is recovered here
+// and turned into a plain error so one such collision doesn't bring
+// down the whole pprof invocation.
+func loadPlugin(path string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("plugin: loading %s: %v", path, r)
+		}
+	}()
+	_, err = plugin.Open(path)
+	return err
+}
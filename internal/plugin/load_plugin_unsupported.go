@@ -0,0 +1,27 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux && !freebsd && !darwin
+
+package plugin
+
+import "fmt"
+
+// loadPlugin reports that dynamic loading is unavailable on this
+// platform: the standard library's "plugin" package only supports
+// Linux, FreeBSD and macOS. Plugins for other platforms must be
+// registered at compile time via Register instead.
+func loadPlugin(path string) error {
+	return fmt.Errorf("plugin: dynamic loading of %s is not supported on this platform; register it at compile time instead", path)
+}
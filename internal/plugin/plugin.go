@@ -16,6 +16,7 @@
 package plugin
 
 import (
+	"crypto/tls"
 	"io"
 	"net/http"
 	"regexp"
@@ -29,10 +30,16 @@ type Options struct {
 	Writer  Writer
 	Flagset FlagSet
 	Fetch   Fetcher
+	Stream  StreamingFetcher
 	Sym     Symbolizer
 	Obj     ObjTool
 	UI      UI
 
+	// DebugInfo resolves separate debug info for stripped binaries
+	// encountered by Obj, such as a debuginfod server. If DebugInfo
+	// is nil, Obj.Open only consults the binary itself.
+	DebugInfo DebugInfoResolver
+
 	// HTTPWrapper takes a pprof http handler as an argument and
 	// returns the actual handler that should be invoked by http.
 	// A typical use is to add authentication before calling the
@@ -41,6 +48,12 @@ type Options struct {
 	// If HTTPWrapper is nil, a default wrapper will be used that
 	// disallows all requests except from the localhost.
 	HTTPWrapper func(http.Handler) http.Handler
+
+	// Creds supplies the credentials that Fetch should attach to
+	// outbound requests for profiles served from endpoints that
+	// require authentication. If Creds is nil, fetches are made
+	// without any credentials attached.
+	Creds CredentialProvider
 }
 
 // Writer provides a mechanism to write data under a certain name,
@@ -94,6 +107,30 @@ type Fetcher interface {
 	Fetch(src string, duration, timeout time.Duration) (*profile.Profile, string, error)
 }
 
+// A CredentialProvider supplies the credentials needed to fetch a
+// profile from src, which may be a bearer token, mTLS client
+// certificate, or cloud-specific IAM credential depending on the
+// endpoint. Either return value may be nil if that form of
+// credential does not apply to src.
+type CredentialProvider interface {
+	Credentials(src string) (http.Header, *tls.Config, error)
+}
+
+// A StreamingFetcher repeatedly collects profiles from src and emits
+// them on the returned channel, enabling tools such as live flame
+// graphs that need more than a single point-in-time profile.
+//
+// Each emitted profile covers the trailing window ending at the time
+// it was produced, and a new profile is emitted roughly every
+// interval until the returned cancel func is called. timeout bounds
+// how long a single collection may take; if it is exceeded that tick
+// is skipped. If the consumer fails to keep up, implementations
+// should drop the oldest pending profile rather than block the
+// collection loop.
+type StreamingFetcher interface {
+	FetchStream(src string, window, interval, timeout time.Duration) (<-chan *profile.Profile, func(), error)
+}
+
 // A Symbolizer introduces symbol information into a profile.
 type Symbolizer interface {
 	Symbolize(mode string, srcs MappingSources, prof *profile.Profile) error
@@ -118,6 +155,14 @@ type ObjTool interface {
 	Disasm(file string, start, end uint64) ([]Inst, error)
 }
 
+// A DebugInfoResolver locates separate debug info for a stripped
+// binary given its build ID, such as split-DWARF companion files or
+// a debuginfod server. ObjTool.Open consults it when the requested
+// binary does not carry its own symbol or line information.
+type DebugInfoResolver interface {
+	Resolve(buildID string) (io.ReadCloser, error)
+}
+
 // An Inst is a single instruction in an assembly listing.
 type Inst struct {
 	Addr     uint64 // virtual address of instruction
@@ -155,6 +200,21 @@ type ObjFile interface {
 	Close() error
 }
 
+// A DebugInfoObjFile is an ObjFile that can additionally serve its
+// own separate debug info, such as that previously resolved by a
+// DebugInfoResolver for a stripped binary. Implementations that
+// don't carry or fetch separate debug info need not implement it;
+// callers should type-assert for it rather than assume every ObjFile
+// satisfies it.
+type DebugInfoObjFile interface {
+	ObjFile
+
+	// DebugInfo returns a reader for the separate debug info
+	// associated with the file. It returns an error if no separate
+	// debug info is available.
+	DebugInfo() (io.ReaderAt, error)
+}
+
 // A Frame describes a single line in a source file.
 type Frame struct {
 	Func string // name of function
@@ -0,0 +1,131 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// A Factory builds a plugin implementation given a FlagSet the
+// factory may use to register its own flags. The returned value must
+// implement one or more of Fetcher, StreamingFetcher, Symbolizer,
+// ObjTool, CredentialProvider, or DebugInfoResolver; Merge
+// merges it into an Options accordingly.
+type Factory func(fs FlagSet) (interface{}, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a named plugin factory available to Merge. There is
+// no -plugin command-line flag; the names Merge instantiates are the
+// ones its caller passes. Register is typically called from an init function, either
+// in a compiled-in package or in a Go plugin loaded from
+// PPROF_PLUGIN_PATH. It panics if name is already registered; for a
+// plugin loaded dynamically from PPROF_PLUGIN_PATH, loadPlugin
+// recovers that panic and reports it as a normal error instead of
+// crashing the process, since such collisions aren't caught at
+// compile time.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("plugin: Register called twice for name %q", name))
+	}
+	registry[name] = factory
+}
+
+// Lookup returns the factory registered under name, if any.
+func Lookup(name string) (Factory, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// Registered returns the names of all currently registered plugins,
+// sorted alphabetically.
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge instantiates the named plugins via their registered
+// factories and merges each result into o. Each name must have been
+// registered with Register, either by a compiled-in package or, on
+// operating systems that support Go's -buildmode=plugin (currently
+// Linux, FreeBSD and macOS), by a shared object discovered under
+// PPROF_PLUGIN_PATH. On other platforms only compile-time
+// registration is available, and Merge returns an error naming any
+// requested plugin it cannot find.
+func Merge(o *Options, fs FlagSet, names []string) error {
+	for _, name := range names {
+		factory, ok := Lookup(name)
+		if !ok {
+			return fmt.Errorf("plugin: no plugin registered under name %q (registered: %v)", name, Registered())
+		}
+		impl, err := factory(fs)
+		if err != nil {
+			return fmt.Errorf("plugin: initializing %q: %v", name, err)
+		}
+		if err := mergeInto(o, name, impl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeInto assigns impl to every Options field whose interface it
+// satisfies. It is an error for a plugin to implement none of them.
+func mergeInto(o *Options, name string, impl interface{}) error {
+	matched := false
+	if f, ok := impl.(Fetcher); ok {
+		o.Fetch = f
+		matched = true
+	}
+	if f, ok := impl.(StreamingFetcher); ok {
+		o.Stream = f
+		matched = true
+	}
+	if s, ok := impl.(Symbolizer); ok {
+		o.Sym = s
+		matched = true
+	}
+	if t, ok := impl.(ObjTool); ok {
+		o.Obj = t
+		matched = true
+	}
+	if c, ok := impl.(CredentialProvider); ok {
+		o.Creds = c
+		matched = true
+	}
+	if r, ok := impl.(DebugInfoResolver); ok {
+		o.DebugInfo = r
+		matched = true
+	}
+	if !matched {
+		return fmt.Errorf("plugin: %q does not implement any known plugin interface", name)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import "testing"
+
+// resetRegistry clears the package-level registry around a test so
+// tests don't leak names into each other.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	saved := registry
+	registry = map[string]Factory{}
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegisterAndLookup(t *testing.T) {
+	resetRegistry(t)
+	factory := func(fs FlagSet) (interface{}, error) { return fakeFetcher{}, nil }
+	Register("test-plugin", factory)
+
+	if _, ok := Lookup("test-plugin"); !ok {
+		t.Fatal("Lookup did not find registered plugin")
+	}
+	if names := Registered(); len(names) != 1 || names[0] != "test-plugin" {
+		t.Errorf("Registered() = %v, want [test-plugin]", names)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	resetRegistry(t)
+	factory := func(fs FlagSet) (interface{}, error) { return fakeFetcher{}, nil }
+	Register("dup", factory)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register did not panic on duplicate name")
+		}
+	}()
+	Register("dup", factory)
+}
+
+func TestMergeMergesMatchingInterfaces(t *testing.T) {
+	resetRegistry(t)
+	Register("fetcher", func(fs FlagSet) (interface{}, error) { return fakeFetcher{}, nil })
+
+	var o Options
+	if err := Merge(&o, nil, []string{"fetcher"}); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if o.Fetch == nil {
+		t.Fatal("Merge did not set Options.Fetch")
+	}
+}
+
+func TestMergeUnknownName(t *testing.T) {
+	resetRegistry(t)
+	var o Options
+	if err := Merge(&o, nil, []string{"missing"}); err == nil {
+		t.Error("Merge with an unregistered name succeeded, want error")
+	}
+}
+
+type implementsNothing struct{}
+
+func TestMergeIntoRejectsUnrecognizedImplementation(t *testing.T) {
+	var o Options
+	if err := mergeInto(&o, "nothing", implementsNothing{}); err == nil {
+		t.Error("mergeInto with a value matching no plugin interface succeeded, want error")
+	}
+}
+
+func TestMergeIntoSetsDebugInfoResolver(t *testing.T) {
+	var o Options
+	resolver := DebuginfodResolver{}
+	if err := mergeInto(&o, "debuginfod", resolver); err != nil {
+		t.Fatalf("mergeInto: %v", err)
+	}
+	if o.DebugInfo == nil {
+		t.Error("mergeInto did not set Options.DebugInfo")
+	}
+}
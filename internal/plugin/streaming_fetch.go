@@ -0,0 +1,108 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+// HTTPStreamingFetcher adapts a Fetcher into a StreamingFetcher by
+// repeatedly calling Fetch every interval and merging the trailing
+// window's worth of profiles with profile.Merge. It is not installed
+// automatically: a nil Options.Stream is not replaced by an
+// HTTPStreamingFetcher wrapping Options.Fetch.
+type HTTPStreamingFetcher struct {
+	// Fetch is the underlying single-shot fetcher used for each tick.
+	Fetch Fetcher
+}
+
+// FetchStream implements the StreamingFetcher interface.
+func (h HTTPStreamingFetcher) FetchStream(src string, window, interval, timeout time.Duration) (<-chan *profile.Profile, func(), error) {
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("FetchStream: interval must be positive, got %v", interval)
+	}
+	if window <= 0 {
+		return nil, nil, fmt.Errorf("FetchStream: window must be positive, got %v", window)
+	}
+	if timeout <= 0 {
+		return nil, nil, fmt.Errorf("FetchStream: timeout must be positive, got %v", timeout)
+	}
+
+	// Buffer of 1 so a slow consumer never blocks the collection loop;
+	// a pending, not-yet-consumed profile is dropped in favor of the
+	// newest one instead.
+	out := make(chan *profile.Profile, 1)
+	done := make(chan struct{})
+	var once sync.Once
+	cancel := func() { once.Do(func() { close(done) }) }
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var recent []*profile.Profile
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			prof, _, err := h.Fetch.Fetch(src, interval, timeout)
+			if err != nil {
+				continue
+			}
+
+			recent = append(recent, prof)
+			recent = trimToWindow(recent, window)
+
+			merged, err := profile.Merge(recent)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case out <- merged:
+			default:
+				// Drop the stale pending profile and replace it with
+				// the latest one.
+				select {
+				case <-out:
+				default:
+				}
+				out <- merged
+			}
+		}
+	}()
+
+	return out, cancel, nil
+}
+
+// trimToWindow drops profiles from the front of recent once their
+// combined duration exceeds window.
+func trimToWindow(recent []*profile.Profile, window time.Duration) []*profile.Profile {
+	var total time.Duration
+	for i := len(recent) - 1; i >= 0; i-- {
+		total += time.Duration(recent[i].DurationNanos)
+		if total > window {
+			return recent[i+1:]
+		}
+	}
+	return recent
+}
@@ -0,0 +1,103 @@
+// Copyright 2014 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+type fakeFetcher struct {
+	fetch func(src string, duration, timeout time.Duration) (*profile.Profile, string, error)
+}
+
+func (f fakeFetcher) Fetch(src string, duration, timeout time.Duration) (*profile.Profile, string, error) {
+	return f.fetch(src, duration, timeout)
+}
+
+func TestFetchStreamRejectsNonPositiveDurations(t *testing.T) {
+	h := HTTPStreamingFetcher{Fetch: fakeFetcher{}}
+	for _, tc := range []struct {
+		name                      string
+		window, interval, timeout time.Duration
+	}{
+		{"zero interval", time.Second, 0, time.Second},
+		{"negative interval", time.Second, -time.Second, time.Second},
+		{"zero window", 0, time.Second, time.Second},
+		{"zero timeout", time.Second, time.Second, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ch, cancel, err := h.FetchStream("src", tc.window, tc.interval, tc.timeout)
+			if err == nil {
+				if cancel != nil {
+					cancel()
+				}
+				t.Fatalf("FetchStream(%v, %v, %v) returned nil error, want error", tc.window, tc.interval, tc.timeout)
+			}
+			if ch != nil || cancel != nil {
+				t.Errorf("FetchStream returned non-nil channel/cancel alongside an error")
+			}
+		})
+	}
+}
+
+func TestFetchStreamEmitsMergedProfiles(t *testing.T) {
+	calls := make(chan struct{}, 10)
+	h := HTTPStreamingFetcher{
+		Fetch: fakeFetcher{
+			fetch: func(src string, duration, timeout time.Duration) (*profile.Profile, string, error) {
+				calls <- struct{}{}
+				return &profile.Profile{DurationNanos: int64(duration)}, src, nil
+			},
+		},
+	}
+
+	ch, cancel, err := h.FetchStream("src", 10*time.Millisecond, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("FetchStream: %v", err)
+	}
+	defer cancel()
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a merged profile")
+	}
+
+	cancel()
+	select {
+	case <-calls:
+	default:
+	}
+}
+
+func TestTrimToWindow(t *testing.T) {
+	mk := func(ns int64) *profile.Profile { return &profile.Profile{DurationNanos: ns} }
+	recent := []*profile.Profile{mk(5), mk(5), mk(5)}
+
+	trimmed := trimToWindow(recent, 7*time.Nanosecond)
+	if len(trimmed) != 1 {
+		t.Fatalf("len(trimmed) = %d, want 1", len(trimmed))
+	}
+	if trimmed[0] != recent[2] {
+		t.Errorf("trimToWindow kept the wrong profile")
+	}
+
+	if got := trimToWindow(recent, 100*time.Nanosecond); len(got) != len(recent) {
+		t.Errorf("trimToWindow(window larger than total) = %d profiles, want %d", len(got), len(recent))
+	}
+}